@@ -0,0 +1,207 @@
+package thermal
+
+import (
+	"fmt"
+	"math"
+)
+
+// FluidProperties are the constant properties of the circulating fluid
+// needed for the Dittus-Boelter film-coefficient correlation used by the
+// borehole heat exchanger models. Unlike Material, these are not carried as
+// temperature-dependent functions - borehole sizing is typically run at a
+// single representative fluid temperature.
+type FluidProperties struct {
+	Rho float64 // density, LB/CF
+	Mu  float64 // dynamic viscosity, LB/HR.FT
+	Cp  float64 // specific heat, BTU/LB.F
+	K   float64 // thermal conductivity, BTU/HR.FT.F
+}
+
+// BoreholeConfig describes a single U-tube or coaxial borehole ground heat
+// exchanger leg pair. Dco/Dci (the casing forming the coaxial annulus) are
+// only read by BoreholeCoaxial; Shank (the two legs' spacing) is only read
+// by BoreholeUTube.
+type BoreholeConfig struct {
+	Dpo, Dpi float64 // inner pipe outside/inside diameter, IN
+	Dco, Dci float64 // coaxial outer casing outside/inside diameter, IN
+	Shank    float64 // center-to-center shank spacing between the two U-tube legs, IN
+	Rb       float64 // borehole radius, IN
+	Pipe     Material // inner pipe (and, for coaxial, casing) wall conductivity
+	Grout    Material
+	Fluid    FluidProperties
+	Mdot     float64 // mass flow rate, LB/HR
+	Tin      float64 // fluid temperature entering the borehole, F
+	Depth    float64 // borehole depth, FT
+	Nstep    int     // amount of RK4 integration steps along depth; 0 defaults to 100
+}
+
+// dittusBoelterH returns the convective film coefficient, BTU/HR.SF.F, from
+// Nu = 0.023*Re^0.8*Pr^0.4 for flow through a duct of hydraulic diameter Dh
+// (FT) and cross-sectional area area (SF).
+func dittusBoelterH(mdot, Dh, area float64, f FluidProperties) float64 {
+	Re := mdot * Dh / (area * f.Mu)
+	Pr := f.Mu * f.Cp / f.K
+	Nu := 0.023 * math.Pow(Re, 0.8) * math.Pow(Pr, 0.4)
+	return Nu * f.K / Dh
+}
+
+// resistances computes the fluid-to-grout (R_fg) and grout-to-grout (R_gg)
+// delta-circuit resistances per unit length, HR.FT.F/BTU, using the
+// Hellstrom/Bennet expressions for a symmetric two-pipe U-tube borehole.
+func (c BoreholeConfig) resistances(Tref float64) (Rfg, Rgg float64) {
+	rpo := c.Dpo / 2.0 / 12.0 // ft
+	kp := c.Pipe.ConductivityAvg(Tref, Tref+1) / 12.0
+	kg := c.Grout.ConductivityAvg(Tref, Tref+1) / 12.0
+
+	// pipe wall conduction resistance
+	Rpipe := math.Log(c.Dpo/c.Dpi) / (2.0 * math.Pi * kp)
+
+	// convective film resistance; Re scaled by the actual mass flow rate
+	d := c.Dpi / 12.0
+	area := math.Pi / 4.0 * d * d
+	h := dittusBoelterH(c.Mdot, d, area, c.Fluid)
+	Rconv := 1.0 / (math.Pi * d * h)
+
+	Rfg = Rpipe + Rconv + 1.0/(2.0*math.Pi*kg)*
+		(math.Log((c.Rb/12.0)/rpo)-0.5*math.Log((2.0*c.Shank/12.0)/(2.0*rpo)))
+
+	Rgg = 1.0 / (math.Pi * kg) *
+		math.Log((c.Shank/12.0)/rpo)
+
+	return Rfg, Rgg
+}
+
+// resistancesCoaxial computes the inner-pipe-to-annulus resistance Rio (the
+// coaxial stand-in for R_gg: the short-circuit path between the two
+// counter-flowing streams, through the inner pipe wall and both its
+// boundary films) and the annulus-to-grout resistance Rag (the stand-in for
+// R_fg: the annulus film, the casing wall, and the grout out to the
+// borehole wall). Unlike a U-tube, the inner-pipe stream has no direct path
+// to the grout - all ground coupling happens through the annulus.
+func (c BoreholeConfig) resistancesCoaxial(Tref float64) (Rio, Rag float64) {
+	kp := c.Pipe.ConductivityAvg(Tref, Tref+1) / 12.0
+	kg := c.Grout.ConductivityAvg(Tref, Tref+1) / 12.0
+
+	// inner pipe bore: convective film of the flow down the center
+	dIn := c.Dpi / 12.0
+	areaIn := math.Pi / 4.0 * dIn * dIn
+	hIn := dittusBoelterH(c.Mdot, dIn, areaIn, c.Fluid)
+	RconvIn := 1.0 / (math.Pi * dIn * hIn)
+	Rpipe := math.Log(c.Dpo/c.Dpi) / (2.0 * math.Pi * kp)
+
+	// annulus: counter-flow between the inner pipe's outside and the
+	// casing's inside, hydraulic diameter = the difference of their
+	// diameters (twice the radial gap)
+	dAnnOut, dAnnIn := c.Dci/12.0, c.Dpo/12.0
+	Dh := dAnnOut - dAnnIn
+	areaAnn := math.Pi / 4.0 * (dAnnOut*dAnnOut - dAnnIn*dAnnIn)
+	hAnn := dittusBoelterH(c.Mdot, Dh, areaAnn, c.Fluid)
+	RconvAnnInner := 1.0 / (math.Pi * dAnnIn * hAnn) // annulus film on the inner pipe's outer wall
+	RconvAnnOuter := 1.0 / (math.Pi * dAnnOut * hAnn) // annulus film on the casing's inner wall
+
+	Rio = Rpipe + RconvIn + RconvAnnInner
+
+	Rcasing := math.Log(c.Dco/c.Dci) / (2.0 * math.Pi * kp)
+	rco := c.Dco / 2.0 / 12.0
+	rb := c.Rb / 12.0
+	Rgrout := math.Log(rb/rco) / (2.0 * math.Pi * kg)
+
+	Rag = RconvAnnOuter + Rcasing + Rgrout
+
+	return Rio, Rag
+}
+
+// shootBorehole integrates the coupled down-leg/up-leg temperature ODE with
+// RK4 and shoots on the unknown up-leg temperature at z=0 (the outlet
+// temperature) until the two legs meet at the same temperature at the
+// borehole bottom (the physical U-bend/turnaround). It returns the outlet
+// temperature and the total heat exchanged (BTU/HR). Rfg1 and Rfg2 are the
+// ground-coupling resistance of leg 1 (down-flow) and leg 2 (up-flow)
+// respectively - equal for a symmetric U-tube, but for a coaxial exchanger
+// leg 1 (the inner pipe) has none, so callers pass math.Inf(1) for Rfg1.
+// Rgg is the resistance of the path directly between the two legs.
+func shootBorehole(Tin float64, depth float64, mdotCp, Rfg1, Rfg2, Rgg float64, Tg func(z float64) float64, n int) (
+	Tout, Q float64, err error) {
+
+	if n <= 0 {
+		n = 100
+	}
+	dz := depth / float64(n)
+
+	deriv := func(z, T1, T2 float64) (dT1, dT2 float64) {
+		q1 := (Tg(z)-T1)/Rfg1 + (T2-T1)/Rgg
+		q2 := (Tg(z)-T2)/Rfg2 + (T1-T2)/Rgg
+		dT1 = q1 / mdotCp
+		dT2 = -q2 / mdotCp
+		return
+	}
+
+	integrate := func(T2start float64) (T1, T2 float64) {
+		T1, T2 = Tin, T2start
+		z := 0.0
+		for i := 0; i < n; i++ {
+			k1a, k1b := deriv(z, T1, T2)
+			k2a, k2b := deriv(z+dz/2, T1+dz/2*k1a, T2+dz/2*k1b)
+			k3a, k3b := deriv(z+dz/2, T1+dz/2*k2a, T2+dz/2*k2b)
+			k4a, k4b := deriv(z+dz, T1+dz*k3a, T2+dz*k3b)
+			T1 += dz / 6.0 * (k1a + 2*k2a + 2*k3a + k4a)
+			T2 += dz / 6.0 * (k1b + 2*k2b + 2*k3b + k4b)
+			z += dz
+		}
+		return
+	}
+
+	residual := func(T2start float64) float64 {
+		T1L, T2L := integrate(T2start)
+		return T1L - T2L
+	}
+
+	// bracket and bisect on the shooting variable: the colder the up-leg
+	// guess, the colder it stays relative to the down-leg at the bottom
+	lo, hi := Tin-200.0, Tin+200.0
+	rLo, rHi := residual(lo), residual(hi)
+	if 0 < rLo*rHi {
+		return 0, 0, fmt.Errorf("could not bracket borehole shooting solution")
+	}
+	var mid float64
+	for i := 0; i < 60; i++ {
+		mid = (lo + hi) / 2.0
+		rMid := residual(mid)
+		if math.Abs(rMid) < 1e-6 {
+			break
+		}
+		if 0 < rLo*rMid {
+			lo, rLo = mid, rMid
+		} else {
+			hi, rHi = mid, rMid
+		}
+	}
+
+	_, T2 := integrate(mid)
+	Tout = T2
+	Q = mdotCp * (Tin - Tout)
+	return Tout, Q, nil
+}
+
+// BoreholeUTube computes the outlet fluid temperature and total heat
+// exchanged for a single U-tube borehole ground heat exchanger leg pair,
+// given a prescribed ground temperature profile Tg(z), z measured in FT
+// from the top of the borehole.
+func BoreholeUTube(c BoreholeConfig, Tg func(z float64) float64) (Tout, Q float64, err error) {
+	Rfg, Rgg := c.resistances(c.Tin)
+	mdotCp := c.Mdot * c.Fluid.Cp
+	return shootBorehole(c.Tin, c.Depth, mdotCp, Rfg, Rfg, Rgg, Tg, c.Nstep)
+}
+
+// BoreholeCoaxial computes the outlet fluid temperature and total heat
+// exchanged for a coaxial borehole heat exchanger: fluid flows down the
+// inner pipe and back up the annulus between the inner pipe and the
+// casing. The inner-pipe leg has no direct path to the ground - only the
+// annulus does - so it reuses the same down-leg/up-leg ODE as BoreholeUTube
+// with the inner pipe's ground-coupling resistance set to infinite and
+// Rio/Rag from resistancesCoaxial standing in for R_gg/R_fg.
+func BoreholeCoaxial(c BoreholeConfig, Tg func(z float64) float64) (Tout, Q float64, err error) {
+	Rio, Rag := c.resistancesCoaxial(c.Tin)
+	mdotCp := c.Mdot * c.Fluid.Cp
+	return shootBorehole(c.Tin, c.Depth, mdotCp, math.Inf(1), Rag, Rio, Tg, c.Nstep)
+}
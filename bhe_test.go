@@ -0,0 +1,82 @@
+package thermal
+
+import "testing"
+
+func testFluid() FluidProperties {
+	return FluidProperties{Rho: 62.4, Mu: 2.7, Cp: 1.0, K: 0.35}
+}
+
+// TestBoreholeUTubeEnergyBalance checks that the reported outlet temperature
+// and heat rate for a U-tube borehole are consistent (Q = mdotCp*(Tin-Tout))
+// and sane (fluid cools when the ground is colder than it).
+func TestBoreholeUTubeEnergyBalance(t *testing.T) {
+	c := BoreholeConfig{
+		Dpo: 1.315, Dpi: 1.097,
+		Shank: 2.0,
+		Rb:    3.0,
+		Pipe:  NewMaterialPolynomial(2.0),
+		Grout: NewMaterialPolynomial(1.0),
+		Fluid: testFluid(),
+		Mdot:  1000.0,
+		Tin:   100.0,
+		Depth: 200.0,
+	}
+	Tg := func(z float64) float64 { return 55.0 }
+
+	Tout, Q, err := BoreholeUTube(c, Tg)
+	if err != nil {
+		t.Fatalf("BoreholeUTube: %v", err)
+	}
+	if Tout >= c.Tin {
+		t.Fatalf("Tout=%v should be cooler than Tin=%v when Tg < Tin", Tout, c.Tin)
+	}
+	mdotCp := c.Mdot * c.Fluid.Cp
+	wantQ := mdotCp * (c.Tin - Tout)
+	if diff := Q - wantQ; diff < -1e-6 || 1e-6 < diff {
+		t.Fatalf("Q = %v, want mdotCp*(Tin-Tout) = %v", Q, wantQ)
+	}
+}
+
+// TestBoreholeCoaxialEnergyBalance is the coaxial analogue of
+// TestBoreholeUTubeEnergyBalance, also checking that it returns different
+// numbers than BoreholeUTube for the same configuration - the two use
+// distinct resistance networks.
+func TestBoreholeCoaxialEnergyBalance(t *testing.T) {
+	c := BoreholeConfig{
+		Dpo: 1.315, Dpi: 1.097,
+		Dco: 4.5, Dci: 4.0,
+		Rb:    5.0,
+		Pipe:  NewMaterialPolynomial(2.0),
+		Grout: NewMaterialPolynomial(1.0),
+		Fluid: testFluid(),
+		Mdot:  1000.0,
+		Tin:   100.0,
+		Depth: 200.0,
+	}
+	Tg := func(z float64) float64 { return 55.0 }
+
+	Tout, Q, err := BoreholeCoaxial(c, Tg)
+	if err != nil {
+		t.Fatalf("BoreholeCoaxial: %v", err)
+	}
+	if Tout >= c.Tin {
+		t.Fatalf("Tout=%v should be cooler than Tin=%v when Tg < Tin", Tout, c.Tin)
+	}
+	mdotCp := c.Mdot * c.Fluid.Cp
+	wantQ := mdotCp * (c.Tin - Tout)
+	if diff := Q - wantQ; diff < -1e-6 || 1e-6 < diff {
+		t.Fatalf("Q = %v, want mdotCp*(Tin-Tout) = %v", Q, wantQ)
+	}
+
+	ToutUTube, _, err := BoreholeUTube(BoreholeConfig{
+		Dpo: c.Dpo, Dpi: c.Dpi, Shank: 2.0, Rb: c.Rb,
+		Pipe: c.Pipe, Grout: c.Grout, Fluid: c.Fluid,
+		Mdot: c.Mdot, Tin: c.Tin, Depth: c.Depth,
+	}, Tg)
+	if err != nil {
+		t.Fatalf("BoreholeUTube: %v", err)
+	}
+	if Tout == ToutUTube {
+		t.Fatalf("BoreholeCoaxial returned the same Tout as BoreholeUTube (%v); expected distinct resistance networks", Tout)
+	}
+}
@@ -0,0 +1,90 @@
+package thermal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+)
+
+// Buried reports heat flow and surface temperatures for a pipe/insulation
+// stack laid underground, replacing the above-ground convective/radiative
+// surface resistance with the classic Kern/ASHRAE shape factor for a
+// horizontal cylinder buried in a semi-infinite medium:
+//
+//	R_soil = ln(2H/r + sqrt((2H/r)^2 - 1)) / (2*pi*k_soil*L)
+//
+// per unit length, falling back to R_soil = ln(4H/D) / (2*pi*k_soil*L) when
+// 2H/r > 8 to avoid the numerically ill-conditioned sqrt term. k_soil is
+// evaluated between Tsoil and the outer surface temperature each iteration,
+// same as the layer conductivities.
+func Buried(o io.Writer, Tservice float64, layers []Layer, Tsoil float64, soil Material, ODpipe, burialDepth float64) (
+	Q float64, T []float64, err error) {
+
+	// nil output
+	if o == nil {
+		var buf bytes.Buffer
+		o = &buf
+	}
+	out := tabwriter.NewWriter(o, 0, 0, 1, ' ', tabwriter.AlignRight)
+	defer func() {
+		out.Flush()
+	}()
+
+	fmt.Fprintf(out, "HEAT FLOW AND SURFACE TEMPERATURES OF BURIED INSULATED PIPE\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "ACTUAL PIPE DIAMETER, IN:\t %6.2f\n", ODpipe)
+	fmt.Fprintf(out, "PIPE SERVICE TEMPERATURE, F:\t %6.2f\n", Tservice)
+	fmt.Fprintf(out, "SOIL TEMPERATURE, F:\t %6.2f\n", Tsoil)
+	fmt.Fprintf(out, "BURIAL DEPTH TO PIPE CENTERLINE, FT:\t %6.2f\n", burialDepth)
+
+	OD, ID := layerDiameters(layers, ODpipe)
+
+	T = initialTemperatures(Tservice, Tsoil, layers)
+
+	var K, R []float64
+	var Rsoil float64
+	Q, K, R, err = solveStack(Tservice, Tsoil, layers, OD, ID, true, T, func(Touter float64) float64 {
+		r := OD[len(layers)-1] / 2.0 / 12.0 // ft
+		// the straight-line seed puts Touter exactly at Tsoil, and
+		// ConductivityAvg divides by the temperature difference
+		Tk := Touter
+		if Tk == Tsoil {
+			Tk = Tsoil + 1
+		}
+		kSoil := soil.ConductivityAvg(Tsoil, Tk)
+		ratio := 2.0 * burialDepth / r
+		var shapeFactor float64
+		if 8.0 < ratio {
+			shapeFactor = math.Log(4.0 * burialDepth / (2.0 * r))
+		} else {
+			shapeFactor = math.Log(ratio + math.Sqrt(ratio*ratio-1.0))
+		}
+		// same outer-area referencing as the layer resistances R[i],
+		// so Rsoil can be summed with RcondSum directly
+		Rsoil = OD[len(layers)-1] / 2.0 * shapeFactor / kSoil
+		return Rsoil
+	})
+	if err != nil {
+		return
+	}
+
+	Q = Q * math.Pi * OD[len(layers)-1] / 12.0
+
+	{
+		// output data
+		fmt.Fprintf(out, "SOIL RESISTANCE, HR.SF.F/BTU:\t %6.4f\n", Rsoil)
+		fmt.Fprintf(out, "EFFECTIVE HEAT SINK TEMPERATURE, F:\t %6.2f\n", Tsoil)
+		fmt.Fprintf(out, "SURFACE OF INSULATION TEMPERATURE, F:\t %6.2f\n", T[len(layers)])
+		fmt.Fprintf(out, "TOTAL HEAT FLOW, BTU/HR.FT:\t %6.2f\n", Q)
+		fmt.Fprintf(out, "\n")
+		fmt.Fprintf(out, "LAYER \tINSULATION \tCONDUCTIVITY \tRESISTANCE \tTEMPERATURE,F\n")
+		fmt.Fprintf(out, "No \tTHICKNESS,in \tBTU.IN/HR.SF.F \tHR.SF.F/BTU \tINSIDE \tOUTSIDE\n")
+		for i, l := range layers {
+			fmt.Fprintf(out, "%d \t%.2f \t%.3f \t%.2f \t%.2f \t%.2f\n",
+				i, l.Thk, K[i], R[i], T[i], T[i+1])
+		}
+	}
+	return
+}
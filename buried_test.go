@@ -0,0 +1,42 @@
+package thermal
+
+import (
+	"math"
+	"testing"
+)
+
+// TestBuriedEnergyBalance checks that Buried's reported heat flow is
+// consistent with the soil resistance and temperature drop it computes: the
+// flux through the soil, times the soil resistance, must reproduce the
+// drop from the insulation surface to the soil temperature.
+func TestBuriedEnergyBalance(t *testing.T) {
+	layers := []Layer{
+		{Thk: 1.0, Mat: NewMaterialPolynomial(0.3)},
+	}
+
+	Q, T, err := Buried(nil, 200.0, layers, 60.0, NewMaterialPolynomial(0.5), 4.0, 3.0)
+	if err != nil {
+		t.Fatalf("Buried: %v", err)
+	}
+	if Q <= 0 {
+		t.Fatalf("Q = %v, want positive heat flow from a hot pipe to cooler soil", Q)
+	}
+	if len(T) != len(layers)+1 {
+		t.Fatalf("len(T) = %d, want %d", len(T), len(layers)+1)
+	}
+	if T[0] <= T[len(layers)] {
+		t.Fatalf("T[0]=%v should be hotter than the insulation surface T[%d]=%v", T[0], len(layers), T[len(layers)])
+	}
+	if T[len(layers)] <= 60.0 {
+		t.Fatalf("insulation surface T=%v should still be above soil temperature 60", T[len(layers)])
+	}
+
+	// OD = 6in, 2H/r = 24 > 8, so R_soil = OD/2*ln(4H/D)/k_soil per SF of
+	// outer surface, with H = 3ft, D = 0.5ft, k_soil = 0.5
+	OD := 4.0 + 2.0*layers[0].Thk
+	Rsoil := OD / 2.0 * math.Log(4.0*3.0/(OD/12.0)) / 0.5
+	flux := Q / (math.Pi * OD / 12.0)
+	if diff := flux*Rsoil - (T[len(layers)] - 60.0); diff < -1e-6 || 1e-6 < diff {
+		t.Fatalf("flux*Rsoil = %v, want surface-to-soil drop %v", flux*Rsoil, T[len(layers)]-60.0)
+	}
+}
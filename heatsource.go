@@ -0,0 +1,132 @@
+package thermal
+
+import (
+	"math"
+	"sort"
+)
+
+// Source is a volumetric heat-generation term carried by a Layer, evaluated
+// at a local temperature T (degree F) and position x within the layer
+// (inches from the layer's inner face). Units are BTU/HR.CF.
+type Source interface {
+	HeatRate(T, x float64) float64
+}
+
+// constantSource is a uniform, temperature- and position-independent
+// generation rate.
+type constantSource struct {
+	q float64
+}
+
+// ConstantSource returns a Source generating a fixed q, BTU/HR.CF,
+// everywhere in the layer - the typical model for self-heating insulation
+// or a lumped electric trace.
+func ConstantSource(q float64) Source {
+	return constantSource{q: q}
+}
+
+func (s constantSource) HeatRate(T, x float64) float64 {
+	return s.q
+}
+
+// tableSource is a generation rate that varies with position, linearly
+// interpolated between tabulated points.
+type tableSource struct {
+	x, q []float64
+}
+
+// TableSource returns a Source whose rate is linearly interpolated from the
+// tabulated (x, q) pairs, x in inches from the layer's inner face and q in
+// BTU/HR.CF. x must be sorted ascending.
+func TableSource(x, q []float64) Source {
+	return tableSource{x: append([]float64{}, x...), q: append([]float64{}, q...)}
+}
+
+func (s tableSource) HeatRate(T, x float64) float64 {
+	n := len(s.x)
+	if n == 0 {
+		return 0
+	}
+	if x <= s.x[0] {
+		return s.q[0]
+	}
+	if s.x[n-1] <= x {
+		return s.q[n-1]
+	}
+	i := sort.SearchFloat64s(s.x, x)
+	x0, x1 := s.x[i-1], s.x[i]
+	q0, q1 := s.q[i-1], s.q[i]
+	return q0 + (q1-q0)*(x-x0)/(x1-x0)
+}
+
+// timeSource is a generation rate that varies with time only. The transient
+// solvers (FlatTransient/CylinderTransient) do not currently account for
+// Layer.Qgen at all, so this is only meaningful for the steady-state
+// `Flat`/`Cylinder`/`Buried` entry points, which evaluate it at t=0.
+type timeSource struct {
+	f func(t float64) float64
+}
+
+// TimeSource returns a Source driven purely by elapsed time, BTU/HR.CF,
+// evaluated at t=0. It is only meaningful for the steady-state
+// `Flat`/`Cylinder`/`Buried` calls - the transient solvers ignore
+// Layer.Qgen, so a TimeSource has no effect there.
+func TimeSource(f func(t float64) float64) Source {
+	return &timeSource{f: f}
+}
+
+func (s *timeSource) HeatRate(T, x float64) float64 {
+	return s.f(0)
+}
+
+// genSubSteps is the amount of sub-nodes used to integrate a layer's
+// generation profile when computing its contribution to flux and
+// temperature drop.
+const genSubSteps = 10
+
+// layerGeneration integrates a layer's Q_gen profile assuming zero entering
+// flux, returning the flux it adds by the time it reaches the outer face
+// (g, BTU/HR.SF referenced to the outer surface area like R) and the
+// temperature drop caused purely by that generation (h, degree F). Callers
+// add g to the flux entering the next layer and subtract h in addition to
+// the usual Q*R drop.
+func layerGeneration(l Layer, OD, ID []float64, i int, isCylinder bool, Tavg float64) (g, h float64) {
+	if l.Qgen == nil {
+		return 0, 0
+	}
+
+	if !isCylinder {
+		dx := l.Thk / genSubSteps
+		K := l.Mat.ConductivityAvg(Tavg, Tavg+1) // already-converged k at the layer's mean temperature
+		var q, T, x float64
+		for k := 0; k < genSubSteps; k++ {
+			xm := x + dx/2
+			qv := l.Qgen.HeatRate(Tavg, xm) // BTU/HR.CF
+			qNext := q + qv*dx/12.0        // dx is inches; volume per unit area wants feet
+			T -= (q + qNext) / 2 * dx / K
+			q = qNext
+			x += dx
+		}
+		return q, -T
+	}
+
+	// cylindrical: march diameter-wise across the layer, reusing the
+	// same outer-area reference as R so flux stays additive with it.
+	K := l.Mat.ConductivityAvg(Tavg, Tavg+1)
+	dd := (OD[i] - ID[i]) / genSubSteps
+	var qLen, T float64 // qLen is total BTU/HR per foot of length
+	d := ID[i]
+	for k := 0; k < genSubSteps; k++ {
+		dNext := d + dd
+		volSeg := math.Pi * (dNext*dNext - d*d) / (4.0 * 144.0) // ft2 per ft length
+		xIn := (d+dNext)/4.0 - ID[i]/2.0                        // offset from the layer's inner face, IN
+		qv := l.Qgen.HeatRate(Tavg, xIn)
+		qNext := qLen + qv*volSeg
+		Rseg := OD[len(OD)-1] / 2.0 * math.Log(dNext/d) / K
+		T -= (qLen + qNext) / 2 / (math.Pi * OD[len(OD)-1] / 12.0) * Rseg // per-foot flow to outer-area flux, as Rseg
+		qLen = qNext
+		d = dNext
+	}
+	g = qLen / (math.Pi * OD[len(OD)-1] / 12.0)
+	return g, -T
+}
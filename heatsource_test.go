@@ -0,0 +1,51 @@
+package thermal
+
+import "testing"
+
+// TestLayerGenerationCylinderOffsetFromInnerFace checks that the cylindrical
+// branch samples Source.HeatRate at the position documented on Source -
+// inches from the layer's inner face - rather than the absolute radius from
+// the pipe centerline. A TableSource built against the documented 0..Thk
+// range should see the same offsets regardless of how far out the layer
+// sits on the pipe.
+func TestLayerGenerationCylinderOffsetFromInnerFace(t *testing.T) {
+	var gotX []float64
+	probe := sourceFunc(func(T, x float64) float64 {
+		gotX = append(gotX, x)
+		return 0
+	})
+	l := Layer{Thk: 1.0, Mat: NewMaterialPolynomial(1.0), Qgen: probe}
+
+	// ID=10 means the layer's inner face sits 5in off the pipe centerline;
+	// offsets into the layer must still run from 0 to Thk, not 5 to 6.
+	OD := []float64{12.0}
+	ID := []float64{10.0}
+	layerGeneration(l, OD, ID, 0, true, 100)
+
+	for _, x := range gotX {
+		if x < 0 || l.Thk < x {
+			t.Fatalf("offset %v out of the documented [0, Thk] range", x)
+		}
+	}
+}
+
+// sourceFunc adapts a plain function to the Source interface for tests.
+type sourceFunc func(T, x float64) float64
+
+func (f sourceFunc) HeatRate(T, x float64) float64 { return f(T, x) }
+
+func TestLayerGenerationFlatUnits(t *testing.T) {
+	l := Layer{Thk: 1.0, Mat: NewMaterialPolynomial(1.0), Qgen: ConstantSource(100)}
+
+	g, h := layerGeneration(l, nil, nil, 0, false, 100)
+
+	wantG := 100.0 * 1.0 / 12.0      // qv*Thk, converted from inches to feet
+	wantH := 100.0 * 1.0 * 1.0 / 24.0 // qv*Thk^2/(2*12*K)
+
+	if diff := g - wantG; diff < -1e-9 || 1e-9 < diff {
+		t.Errorf("g = %v, want %v", g, wantG)
+	}
+	if diff := h - wantH; diff < -1e-9 || 1e-9 < diff {
+		t.Errorf("h = %v, want %v", h, wantH)
+	}
+}
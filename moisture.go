@@ -0,0 +1,164 @@
+package thermal
+
+import (
+	"io"
+	"math"
+)
+
+// satPressure returns the saturation vapor pressure at a temperature,
+// degree F, via the Magnus formula. Result unit: Pa.
+func satPressure(F float64) float64 {
+	C := (F - 32.0) * 5.0 / 9.0
+	return 610.94 * math.Exp(17.625*C/(C+243.04))
+}
+
+// DewPoint returns the dew-point temperature, degree F, of air at a given
+// temperature Tamb (degree F) and relative humidity RH (percent), by
+// inverting the Magnus formula.
+func DewPoint(Tamb, RH float64) float64 {
+	C := (Tamb - 32.0) * 5.0 / 9.0
+	gamma := math.Log(RH/100.0) + 17.625*C/(243.04+C)
+	dC := 243.04 * gamma / (17.625 - gamma)
+	return dC*9.0/5.0 + 32.0
+}
+
+// moistureMaterial blends a layer's dry and moisture-saturated conductivity
+// linearly by a local wetness fraction in [0, 1], as used by the
+// condensation-iteration pass in hygrothermalCalc.
+type moistureMaterial struct {
+	dry, wet Material
+	wetness  float64
+}
+
+func (m moistureMaterial) ConductivityAvg(F1, F2 float64) float64 {
+	return (1-m.wetness)*m.dry.ConductivityAvg(F1, F2) + m.wetness*m.wet.ConductivityAvg(F1, F2)
+}
+
+// FlatHygrothermal is the moisture-coupled analogue of Flat: in addition to
+// the steady temperature profile, it runs a Glaser-style vapor diffusion
+// pass and flags interfaces where condensation risk exists.
+func FlatHygrothermal(o io.Writer, Tservice, RHservice float64, layers []Layer, Tamb, RHamb float64, es *ExternalSurface) (
+	Q float64, T []float64, condensation []bool, err error) {
+	return hygrothermalCalc(o, Tservice, RHservice, layers, Tamb, RHamb, es, -1.0)
+}
+
+// CylinderHygrothermal is the cylindrical analogue of FlatHygrothermal.
+func CylinderHygrothermal(o io.Writer, Tservice, RHservice float64, layers []Layer, Tamb, RHamb float64, es *ExternalSurface, ODpipe float64) (
+	Q float64, T []float64, condensation []bool, err error) {
+	return hygrothermalCalc(o, Tservice, RHservice, layers, Tamb, RHamb, es, ODpipe)
+}
+
+// hygrothermalCalc runs the usual layered thermal solve, then a linear
+// vapor-pressure (Glaser) pass across each layer's permeance resistance
+// 1/Perm, comparing the result against the saturation pressure implied by
+// the converged temperatures. When condensation is flagged at an interface
+// and the adjacent layer declares a WetMat, the thermal solve is repeated
+// with that layer's conductivity blended toward WetMat until the blend
+// fractions stop changing, for at most maxMoistureIter passes.
+func hygrothermalCalc(o io.Writer, Tservice, RHservice float64, layers []Layer, Tamb, RHamb float64, es *ExternalSurface, ODpipe float64) (
+	Q float64, T []float64, condensation []bool, err error) {
+
+	active := append([]Layer{}, layers...)
+
+	const maxMoistureIter = 5
+	var pv, psat []float64
+	for iter := 0; iter < maxMoistureIter; iter++ {
+		Q, T, err = calc(nil, Tservice, active, Tamb, es, ODpipe)
+		if err != nil {
+			return
+		}
+
+		pv, psat = glaserProfile(active, T, RHservice, RHamb)
+		changed := false
+		for i := range active {
+			wet := psat[i] < pv[i] || psat[i+1] < pv[i+1]
+			if !wet || layers[i].WetMat == nil {
+				continue
+			}
+			wetness := moistureFraction(pv[i], psat[i], pv[i+1], psat[i+1])
+			next := moistureMaterial{dry: layers[i].Mat, wet: layers[i].WetMat, wetness: wetness}
+			if cur, ok := active[i].Mat.(moistureMaterial); !ok || cur.wetness != next.wetness {
+				changed = true
+			}
+			active[i].Mat = next
+		}
+		if !changed {
+			break
+		}
+	}
+
+	// final pass with the converged (possibly moisture-blended) layers,
+	// this time writing the usual thermal report to o
+	Q, T, err = calc(o, Tservice, active, Tamb, es, ODpipe)
+	if err != nil {
+		return
+	}
+	pv, psat = glaserProfile(active, T, RHservice, RHamb)
+
+	condensation = make([]bool, len(T))
+	for i := range T {
+		condensation[i] = psat[i] < pv[i]
+	}
+	return
+}
+
+// moistureFraction turns how far vapor pressure exceeds saturation pressure
+// at a layer's two interfaces into a 0..1 blend fraction toward WetMat.
+func moistureFraction(pv0, psat0, pv1, psat1 float64) float64 {
+	f := func(pv, psat float64) float64 {
+		if psat <= 0 {
+			return 0
+		}
+		r := (pv - psat) / psat
+		if r < 0 {
+			return 0
+		}
+		if 1 < r {
+			return 1
+		}
+		return r
+	}
+	w := f(pv0, psat0)
+	if w2 := f(pv1, psat1); w2 > w {
+		w = w2
+	}
+	return w
+}
+
+// glaserProfile computes the linear vapor pressure p_v and saturation
+// pressure p_sat, both Pa, at every node of the temperature profile T.
+// Layers with Perm <= 0 contribute no permeance resistance, so p_v does
+// not drop across them; when no layer has Perm > 0, p_v stays at the
+// service-side value at every node.
+func glaserProfile(layers []Layer, T []float64, RHservice, RHamb float64) (pv, psat []float64) {
+	n := len(T)
+	psat = make([]float64, n)
+	for i, t := range T {
+		psat[i] = satPressure(t)
+	}
+
+	Rv := make([]float64, len(layers))
+	var RvSum float64
+	for i, l := range layers {
+		if 0 < l.Perm {
+			Rv[i] = 1.0 / l.Perm
+		}
+		RvSum += Rv[i]
+	}
+
+	pvIn := RHservice / 100.0 * psat[0]
+	pvOut := RHamb / 100.0 * psat[n-1]
+
+	pv = make([]float64, n)
+	pv[0] = pvIn
+	cum := 0.0
+	for i := range layers {
+		cum += Rv[i]
+		frac := 0.0
+		if 0 < RvSum {
+			frac = cum / RvSum
+		}
+		pv[i+1] = pvIn + frac*(pvOut-pvIn)
+	}
+	return pv, psat
+}
@@ -0,0 +1,41 @@
+package thermal
+
+import "testing"
+
+// TestDewPointRoundTrip checks that DewPoint inverts satPressure: the dew
+// point of air at a given RH is the temperature whose saturation pressure
+// equals that air's actual vapor pressure.
+func TestDewPointRoundTrip(t *testing.T) {
+	Tamb, RH := 75.0, 50.0
+	dp := DewPoint(Tamb, RH)
+
+	pv := RH / 100.0 * satPressure(Tamb)
+	psatDp := satPressure(dp)
+	if diff := pv - psatDp; diff < -1e-6 || 1e-6 < diff {
+		t.Fatalf("satPressure(DewPoint(Tamb,RH))=%v, want actual vapor pressure %v", psatDp, pv)
+	}
+	if dp >= Tamb {
+		t.Fatalf("dew point %v should be below the air temperature %v at RH<100", dp, Tamb)
+	}
+}
+
+// TestFlatHygrothermalDryStackUnflagged checks that FlatHygrothermal returns
+// a condensation flag per node and leaves a low-humidity stack unflagged.
+func TestFlatHygrothermalDryStackUnflagged(t *testing.T) {
+	layers := []Layer{
+		{Thk: 0.5, Mat: NewMaterialPolynomial(0.3), Perm: 1.0},
+	}
+
+	_, T, condensation, err := FlatHygrothermal(nil, 200.0, 10.0, layers, 70.0, 10.0, Surf(1.5))
+	if err != nil {
+		t.Fatalf("FlatHygrothermal: %v", err)
+	}
+	if len(condensation) != len(T) {
+		t.Fatalf("len(condensation) = %d, want len(T) = %d", len(condensation), len(T))
+	}
+	for i, c := range condensation {
+		if c {
+			t.Fatalf("dry stack (RH=10%%) flagged condensation at node %d", i)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package thermal
+
+import (
+	"fmt"
+	"math"
+)
+
+// layerDiameters computes inside/outside diameters of every layer given the
+// pipe outside diameter ODpipe (or a negative value for a flat stack).
+func layerDiameters(layers []Layer, ODpipe float64) (OD, ID []float64) {
+	OD = make([]float64, len(layers))
+	ID = make([]float64, len(layers))
+	ID[0] = ODpipe
+	for i := range layers {
+		if 0 < i {
+			ID[i] = OD[i-1]
+		}
+		OD[i] = ID[i] + 2.0*layers[i].Thk
+	}
+	return
+}
+
+// initialTemperatures returns a straight-line temperature guess across the
+// layer stack, used to seed the fixed-point iteration in solveStack.
+func initialTemperatures(Tservice, Tref float64, layers []Layer) []float64 {
+	T := make([]float64, len(layers)+1)
+	ThkSum := 0.0
+	for _, l := range layers {
+		ThkSum += l.Thk
+	}
+	Tdelta := Tservice - Tref
+	for i := range T {
+		if i == 0 {
+			T[0] = Tservice
+			continue
+		}
+		T[i] = T[i-1] - layers[i-1].Thk/ThkSum*Tdelta
+	}
+	return T
+}
+
+// solveStack iterates the layer stack's nodal temperatures to convergence,
+// shared by the above-ground (Flat/Cylinder) and buried entry points. rext
+// supplies the resistance of whatever sits outside the last layer -
+// convective/radiative film for above-ground, soil shape-factor resistance
+// for Buried - as a function of the current outer-surface temperature
+// estimate, since that resistance is itself temperature dependent. T is
+// updated in place; Q, K and R are returned.
+func solveStack(Tservice, Tref float64, layers []Layer, OD, ID []float64, isCylinder bool, T []float64,
+	rext func(Touter float64) float64) (Q float64, K, R []float64, err error) {
+
+	K = make([]float64, len(layers))
+	R = make([]float64, len(layers))
+	G := make([]float64, len(layers)) // flux added by a layer's own generation
+	H := make([]float64, len(layers)) // temperature drop caused by that generation
+
+	var iter, iterMax int64 = 0, 2000
+	for ; iter < iterMax; iter++ {
+		Rext := rext(T[len(layers)])
+
+		var RcondSum, QgenTotal, TgenDrop, CrossTerm float64
+		var cumG float64
+		for i := range layers {
+			K[i] = layers[i].Mat.ConductivityAvg(T[i], T[i+1])
+			if isCylinder {
+				R[i] = OD[len(layers)-1] / 2.0 * math.Log(OD[i]/ID[i]) / K[i]
+			} else {
+				R[i] = layers[i].Thk / K[i]
+			}
+			RcondSum += R[i]
+			G[i], H[i] = layerGeneration(layers[i], OD, ID, i, isCylinder, (T[i]+T[i+1])/2.0)
+			// CrossTerm accounts for generation upstream of layer i
+			// (cumG, the flux already running when layer i is entered)
+			// dropping across layer i's own resistance - the same term
+			// Qrun*R[i] below applies in the per-layer recursion, just
+			// summed up front so Qin can be solved in closed form.
+			CrossTerm += cumG * R[i]
+			cumG += G[i]
+			QgenTotal += G[i]
+			TgenDrop += H[i]
+		}
+
+		// heat flux entering the stack on the service side, solved in
+		// closed form: the flux that, once layer generation is added in
+		// along the way, balances the exit resistance Rext at the far
+		// (ambient or soil) side
+		Qin := ((Tservice - Tref - TgenDrop - CrossTerm) - QgenTotal*Rext) / (Rext + RcondSum)
+
+		// iteration criteria
+		tol := 0.0
+		Qrun := Qin
+		for i := range layers {
+			Ts := T[i] - Qrun*R[i] - H[i]
+			tol += math.Abs(T[i+1] - Ts)
+			T[i+1] = Ts // store data
+			Qrun += G[i]
+		}
+		Q = Qrun
+		if math.Abs(tol) < 1e-5 {
+			break
+		}
+	}
+	if iterMax <= iter {
+		err = fmt.Errorf("not enougnt iterations")
+		return
+	}
+	return
+}
@@ -117,6 +117,29 @@ func (m MaterialType3) ConductivityAvg(F1, F2 float64) float64 {
 type Layer struct {
 	Thk float64
 	Mat Material
+
+	// Rho is density of layer material, lb/ft3. Used only by the
+	// transient solvers; steady-state `calc` ignores it.
+	Rho float64
+
+	// Cp is specific heat of layer material, BTU/lb.F. Used only by
+	// the transient solvers; steady-state `calc` ignores it.
+	Cp float64
+
+	// Qgen is an optional volumetric heat source within the layer, e.g.
+	// a heat-traced pipe or self-heating insulation. Nil means no
+	// generation, the pure-resistance behaviour this package had before.
+	Qgen Source
+
+	// Perm is the layer's water vapor permeability, GRAIN/(HR.SF.INHG).
+	// Zero (the default) adds no vapor resistance in the hygrothermal
+	// Glaser pass. Ignored by the pure thermal solvers.
+	Perm float64
+
+	// WetMat is the layer's conductivity once moisture-saturated. Nil
+	// means the hygrothermal solve only flags condensation risk without
+	// adjusting conductivity.
+	WetMat Material
 }
 
 // ExternalSurface is property of thermal surface
@@ -246,71 +269,19 @@ func calc(o io.Writer, Tservice float64, layers []Layer, Tamb float64, es *Exter
 	}
 
 	// calculate diameters per layers
-	OD := make([]float64, len(layers))
-	ID := make([]float64, len(layers))
-	{
-		ID[0] = ODpipe
-		for i := range layers {
-			if 0 < i {
-				ID[i] = OD[i-1]
-			}
-			OD[i] = ID[i] + 2.0*layers[i].Thk
-		}
-	}
+	OD, ID := layerDiameters(layers, ODpipe)
 
 	// temperature initialization
-	T = make([]float64, len(layers)+1)
-	R := make([]float64, len(layers))
-	K := make([]float64, len(layers))
-	{
-		ThkSum := 0.0
-		for _, l := range layers {
-			ThkSum += l.Thk
-		}
-		Tdelta := Tservice - Tamb
-		for i := range T {
-			if i == 0 {
-				T[0] = Tservice
-				continue
-			}
-			T[i] = T[i-1] - layers[i-1].Thk/ThkSum*Tdelta
-		}
-	}
+	T = initialTemperatures(Tservice, Tamb, layers)
 
-	var iter, iterMax int64 = 0, 2000
-	for ; iter < iterMax; iter++ {
-		// symmary
-		var Rsum float64
+	var K, R []float64
+	Q, K, R, err = solveStack(Tservice, Tamb, layers, OD, ID, isCylinder, T, func(Touter float64) float64 {
 		if !es.isSurf {
-			es.surcof(OD[len(layers)-1], T[len(layers)], Tamb, isCylinder)
+			es.surcof(OD[len(layers)-1], Touter, Tamb, isCylinder)
 		}
-		Rsum = 1.0 / es.surf
-		for i := range layers {
-			K[i] = layers[i].Mat.ConductivityAvg(T[i], T[i+1])
-			if isCylinder {
-				R[i] = OD[len(layers)-1] / 2.0 * math.Log(OD[i]/ID[i]) / K[i]
-			} else {
-				R[i] = layers[i].Thk / K[i]
-			}
-			Rsum += R[i]
-		}
-
-		// heat flux
-		Q = (Tservice - Tamb) / Rsum
-
-		// iteration criteria
-		tol := 0.0
-		for i := range layers {
-			Ts := T[i] - Q*R[i]
-			tol += math.Abs(T[i+1] - Ts)
-			T[i+1] = Ts // store data
-		}
-		if math.Abs(tol) < 1e-5 {
-			break
-		}
-	}
-	if iterMax <= iter {
-		err = fmt.Errorf("not enougnt iterations")
+		return 1.0 / es.surf
+	})
+	if err != nil {
 		return
 	}
 
@@ -0,0 +1,248 @@
+package thermal
+
+import (
+	"fmt"
+	"math"
+)
+
+// TimeStep is a single recorded state of a transient solve.
+type TimeStep struct {
+	T []float64 // nodal temperatures, F, same layout as steady `calc` T
+	Q float64   // heat flux at the recorded time, BTU/HR.SF (or BTU/HR.FT for cylinder)
+	t float64   // elapsed time, HR
+}
+
+// FlatTransient is a time-domain analogue of Flat: each layer is split into
+// N sub-nodes and marched forward with an implicit Crank-Nicolson
+// finite-difference scheme. Tservice and Tamb are evaluated at each time
+// step, so periodic or ramped boundary conditions can be modeled.
+func FlatTransient(Tservice, Tamb func(t float64) float64, layers []Layer, es *ExternalSurface,
+	N int, dt, tEnd, tol float64) (steps []TimeStep, err error) {
+	return transientCalc(Tservice, Tamb, layers, es, -1.0, N, dt, tEnd, tol)
+}
+
+// CylinderTransient is the cylindrical analogue of FlatTransient, discretizing
+// a pipe/insulation stack radially about ODpipe.
+func CylinderTransient(Tservice, Tamb func(t float64) float64, layers []Layer, es *ExternalSurface, ODpipe float64,
+	N int, dt, tEnd, tol float64) (steps []TimeStep, err error) {
+	return transientCalc(Tservice, Tamb, layers, es, ODpipe, N, dt, tEnd, tol)
+}
+
+// transientCalc assembles the tridiagonal nodal system for the layer stack
+// and marches it forward in time with the Thomas algorithm. Each layer
+// contributes N sub-nodes; adjacent layers share their boundary node, so the
+// total node count is len(layers)*N + 1. Nodal capacitance is lumped as
+// C_i = rho*cp*dx*A and inter-node conductance as G_{i,i+1} = k*A/dx (flat)
+// or G_{i,i+1} = 2*pi*k/ln(r_{i+1}/r_i) per unit length (cylinder).
+func transientCalc(Tservice, Tamb func(t float64) float64, layers []Layer, es *ExternalSurface, ODpipe float64,
+	N int, dt, tEnd, tol float64) (steps []TimeStep, err error) {
+
+	if N < 1 {
+		return nil, fmt.Errorf("amount of sub-nodes per layer must be positive, found: %d", N)
+	}
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("no layers")
+	}
+
+	isCylinder := 0.0 < ODpipe
+
+	// diameter (cylinder) or flat offset of every node, IN, used for area
+	// and conductance calculations
+	nNodes := len(layers)*N + 1
+	x := make([]float64, nNodes)
+	layerOf := make([]int, nNodes) // index of the layer a node's outward step belongs to
+	{
+		pos := ODpipe
+		x[0] = pos
+		idx := 1
+		for li, l := range layers {
+			dx := l.Thk / float64(N)
+			if isCylinder {
+				dx *= 2.0 // Thk is radial, x is a diameter
+			}
+			for k := 0; k < N; k++ {
+				pos += dx
+				x[idx] = pos
+				layerOf[idx-1] = li
+				idx++
+			}
+		}
+	}
+
+	area := func(xi float64) float64 {
+		if isCylinder {
+			return 2.0 * math.Pi * (xi / 2.0 / 12.0) // per foot of length, radius in feet
+		}
+		return 1.0
+	}
+
+	conductance := func(i int, k float64) float64 {
+		// conductance between node i and node i+1
+		k /= 12.0 // BTU.IN/HR.SF.F to BTU/HR.FT.F
+		if isCylinder {
+			r1 := x[i] / 2.0 / 12.0
+			r2 := x[i+1] / 2.0 / 12.0
+			return 2.0 * math.Pi * k / math.Log(r2/r1)
+		}
+		dx := (x[i+1] - x[i]) / 12.0
+		return k * area(x[i]) / dx
+	}
+
+	// thickness of the cell between node i and node i+1, FT
+	width := func(i int) float64 {
+		w := (x[i+1] - x[i]) / 12.0
+		if isCylinder {
+			w /= 2.0 // radial step from a diameter step
+		}
+		return w
+	}
+
+	capacitance := func(i int) float64 {
+		// half of the neighbouring cell(s) contribute to node i
+		var c float64
+		if 0 < i {
+			l := layers[layerOf[i-1]]
+			dx := width(i - 1)
+			c += l.Rho * l.Cp * area(x[i]) * dx / 2.0
+		}
+		if i < nNodes-1 {
+			l := layers[layerOf[i]]
+			dx := width(i)
+			c += l.Rho * l.Cp * area(x[i]) * dx / 2.0
+		}
+		return c
+	}
+
+	// ramped initial profile, linear in node position: seeding every node
+	// with the same temperature would make the first ConductivityAvg(T,T)
+	// call divide by zero, since every shipped Material integrates a rate
+	// over (F2-F1)
+	T := make([]float64, nNodes)
+	{
+		Tin0, Tamb0 := Tservice(0), Tamb(0)
+		span := x[nNodes-1] - x[0]
+		for i := range T {
+			T[i] = Tin0 - (x[i]-x[0])/span*(Tin0-Tamb0)
+		}
+	}
+
+	const theta = 0.5 // Crank-Nicolson blend
+	t := 0.0
+	steps = append(steps, TimeStep{T: append([]float64{}, T...), Q: 0, t: t})
+
+	for t < tEnd {
+		h := dt
+		if tEnd < t+h {
+			h = tEnd - t
+		}
+
+		var Tnew []float64
+		for {
+			Tnew, err = transientStep(T, layers, es, x, layerOf, area, nNodes, isCylinder,
+				Tservice(t+h), Tamb(t+h), conductance, capacitance, h, theta)
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range Tnew {
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					return nil, fmt.Errorf("transient solve diverged to a non-finite temperature at t=%g", t+h)
+				}
+			}
+
+			maxDelta := 0.0
+			for i := range Tnew {
+				if d := math.Abs(Tnew[i] - T[i]); maxDelta < d {
+					maxDelta = d
+				}
+			}
+			if maxDelta <= tol || h < 1e-6 {
+				break
+			}
+			h /= 2.0
+		}
+
+		t += h
+		T = Tnew
+
+		Q := conductance(0, layers[0].Mat.ConductivityAvg(T[0], T[1])) * (T[0] - T[1])
+		steps = append(steps, TimeStep{T: append([]float64{}, T...), Q: Q, t: t})
+	}
+
+	return steps, nil
+}
+
+// transientStep assembles and solves one implicit Crank-Nicolson step with
+// the Thomas algorithm. Node 0 is a Dirichlet boundary fixed at Tservice;
+// the last node sees a convective boundary condition h*(Ts - Tamb).
+func transientStep(T []float64, layers []Layer, es *ExternalSurface, x []float64, layerOf []int, area func(float64) float64,
+	n int, isCylinder bool, Tservice, Tamb float64, conductance func(int, float64) float64, capacitance func(int) float64,
+	dt, theta float64) ([]float64, error) {
+
+	G := make([]float64, n-1)
+	for i := 0; i < n-1; i++ {
+		k := layers[layerOf[i]].Mat.ConductivityAvg(T[i], T[i+1])
+		G[i] = conductance(i, k)
+	}
+
+	if !es.isSurf {
+		es.surcof(x[n-1], T[n-1], Tamb, isCylinder)
+	}
+	Gext := es.surf * area(x[n-1])
+
+	a := make([]float64, n) // sub-diagonal
+	b := make([]float64, n) // diagonal
+	c := make([]float64, n) // super-diagonal
+	d := make([]float64, n) // right-hand side
+
+	// node 0: Dirichlet
+	b[0] = 1.0
+	c[0] = 0.0
+	d[0] = Tservice
+
+	for i := 1; i < n-1; i++ {
+		C := capacitance(i) / dt
+		a[i] = -theta * G[i-1]
+		c[i] = -theta * G[i]
+		b[i] = C + theta*(G[i-1]+G[i])
+		d[i] = C*T[i] + (1-theta)*(G[i-1]*(T[i-1]-T[i])+G[i]*(T[i+1]-T[i]))
+	}
+
+	// last node: convective boundary to ambient
+	C := capacitance(n - 1) / dt
+	a[n-1] = -theta * G[n-2]
+	b[n-1] = C + theta*(G[n-2]+Gext)
+	d[n-1] = C*T[n-1] + theta*Gext*Tamb + (1-theta)*(G[n-2]*(T[n-2]-T[n-1])+Gext*(Tamb-T[n-1]))
+
+	return thomas(a, b, c, d)
+}
+
+// thomas solves a tridiagonal system a[i]*x[i-1] + b[i]*x[i] + c[i]*x[i+1] = d[i]
+// via the Thomas algorithm. a[0] and c[len-1] are ignored.
+func thomas(a, b, c, d []float64) ([]float64, error) {
+	n := len(d)
+	if n == 0 {
+		return nil, fmt.Errorf("empty tridiagonal system")
+	}
+	cp := make([]float64, n)
+	dp := make([]float64, n)
+
+	cp[0] = c[0] / b[0]
+	dp[0] = d[0] / b[0]
+	for i := 1; i < n; i++ {
+		m := b[i] - a[i]*cp[i-1]
+		if m == 0 {
+			return nil, fmt.Errorf("singular tridiagonal system at row %d", i)
+		}
+		if i < n-1 {
+			cp[i] = c[i] / m
+		}
+		dp[i] = (d[i] - a[i]*dp[i-1]) / m
+	}
+
+	x := make([]float64, n)
+	x[n-1] = dp[n-1]
+	for i := n - 2; 0 <= i; i-- {
+		x[i] = dp[i] - cp[i]*x[i+1]
+	}
+	return x, nil
+}
@@ -0,0 +1,27 @@
+package thermal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFlatTransientNoNaN(t *testing.T) {
+	layer := Layer{Thk: 1.0, Mat: NewMaterialPolynomial(1.0), Rho: 50, Cp: 0.2}
+	Tservice := func(float64) float64 { return 200 }
+	Tamb := func(float64) float64 { return 70 }
+
+	steps, err := FlatTransient(Tservice, Tamb, []Layer{layer}, Surf(1.5), 4, 0.1, 1.0, 0.5)
+	if err != nil {
+		t.Fatalf("FlatTransient: %v", err)
+	}
+	if len(steps) == 0 {
+		t.Fatalf("expected at least one recorded step")
+	}
+	for _, s := range steps {
+		for i, v := range s.T {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				t.Fatalf("non-finite temperature at t=%v node=%d: %v", s.t, i, v)
+			}
+		}
+	}
+}